@@ -17,6 +17,7 @@ type Constructor func(http.Handler) http.Handler
 type Chain struct {
 	constructors []Constructor
 	endware      []Endware
+	endwareOrder EndwareOrder
 }
 
 // New creates a new chain,
@@ -24,16 +25,29 @@ type Chain struct {
 // New serves no other function,
 // constructors are only called upon a call to Then().
 func New(constructors ...Constructor) Chain {
-	return Chain{append(([]Constructor)(nil), constructors...), nil}
+	return Chain{append(([]Constructor)(nil), constructors...), nil, EndwareFIFO}
 }
 
 // Then chains the middleware and endware and returns the final http.Handler.
 //     New(m1, m2, m3).After(e1, e2, e3).Then(h)
 // is equivalent to:
-//     m1(m2(m3(h(e1(e2(e3))))))
+//     m1(m2(m3(h)))(w, r); e1(w, r); e2(w, r); e3(w, r)
 // When the request comes in, it will be passed to m1, then m2, then m3,
-// then the given handler (who serves the response), then e1, e2, e3
-// (assuming every middleware/endware calls the following one).
+// then the given handler (who serves the response). Only once that whole
+// stack has returned - i.e. once m1, m2 and m3 have finished their own
+// post-processing too - do e1, e2, e3 run, in that order (EndwareFIFO) or
+// reversed (EndwareLIFO); see WithEndwareOrder.
+//
+// If the chain has any endware, w is replaced with a *ResponseRecorder
+// for the duration of the request, so endware can inspect the status
+// code, bytes written and duration - via the ResponseInfo interface,
+// not a *ResponseRecorder type assertion; see ResponseInfo for why:
+//     chain.After(func(w http.ResponseWriter, r *http.Request) {
+//         rec := w.(alice.ResponseInfo)
+//         log.Printf("%s %d %dB %s", r.URL.Path, rec.Status(), rec.Size(), rec.Duration())
+//     })
+// Chains with no endware pass w through untouched, so the common case
+// of a chain built purely from constructors costs nothing extra.
 //
 // A chain can be safely reused by calling Then() several times.
 //     stdStack := alice.New(ratelimitHandler, csrfHandler).After(loggingHandler)
@@ -50,19 +64,20 @@ func (c Chain) Then(h http.Handler) http.Handler {
 		h = http.DefaultServeMux
 	}
 
-	h = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		h.ServeHTTP(w, r)
-
-		for _, endwareFn := range c.endware {
-			endwareFn(w, r)
-		}
-	})
-
 	for i := range c.constructors {
 		h = c.constructors[len(c.constructors)-1-i](h)
 	}
 
-	return h
+	final := h
+	if len(c.endware) == 0 {
+		return final
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := newResponseRecorder(w)
+		final.ServeHTTP(rec, r)
+		runEndware(c.endware, c.endwareOrder, rec, r)
+	})
 }
 
 // ThenFunc works identically to Then, but takes
@@ -95,7 +110,67 @@ func (c Chain) Append(constructors ...Constructor) Chain {
 	newCons = append(newCons, c.constructors...)
 	newCons = append(newCons, constructors...)
 
-	return Chain{newCons, c.endware}
+	return Chain{newCons, c.endware, c.endwareOrder}
+}
+
+// Before extends a chain, adding the specified constructors as the
+// first ones in the request flow, symmetric to Append.
+//
+// Before returns a new chain, leaving the original one untouched.
+// The new chain will have the original chain's endwares.
+//
+//     stdChain := alice.New(m3, m4)
+//     extChain := stdChain.Before(m1, m2)
+//     // requests in stdChain go m3 -> m4
+//     // requests in extChain go m1 -> m2 -> m3 -> m4
+func (c Chain) Before(constructors ...Constructor) Chain {
+	newCons := make([]Constructor, 0, len(constructors)+len(c.constructors))
+	newCons = append(newCons, constructors...)
+	newCons = append(newCons, c.constructors...)
+
+	return Chain{newCons, c.endware, c.endwareOrder}
+}
+
+// Insert splices the specified constructors into a chain at pos,
+// shifting the constructors already at pos and beyond to come after
+// them. Insert returns a new chain, leaving the original one untouched
+// and its endware unchanged.
+//
+// Insert panics if pos is out of range, i.e. pos < 0 or
+// pos > len(c.constructors).
+//
+//     stdChain := alice.New(auth, ratelimit)
+//     tracedChain := stdChain.Insert(1, tracing)
+//     // requests in stdChain   go auth -> ratelimit -> handler
+//     // requests in tracedChain go auth -> tracing -> ratelimit -> handler
+func (c Chain) Insert(pos int, constructors ...Constructor) Chain {
+	if pos < 0 || pos > len(c.constructors) {
+		panic("alice: Chain.Insert: position out of range")
+	}
+
+	newCons := make([]Constructor, 0, len(c.constructors)+len(constructors))
+	newCons = append(newCons, c.constructors[:pos]...)
+	newCons = append(newCons, constructors...)
+	newCons = append(newCons, c.constructors[pos:]...)
+
+	return Chain{newCons, c.endware, c.endwareOrder}
+}
+
+// Remove returns a new chain with the n constructors starting at pos
+// removed, leaving the original chain and its endware untouched.
+//
+// Remove panics if the range is out of bounds, i.e. pos < 0, n < 0,
+// or pos+n > len(c.constructors).
+func (c Chain) Remove(pos, n int) Chain {
+	if pos < 0 || n < 0 || pos+n > len(c.constructors) {
+		panic("alice: Chain.Remove: position out of range")
+	}
+
+	newCons := make([]Constructor, 0, len(c.constructors)-n)
+	newCons = append(newCons, c.constructors[:pos]...)
+	newCons = append(newCons, c.constructors[pos+n:]...)
+
+	return Chain{newCons, c.endware, c.endwareOrder}
 }
 
 // Extend extends a chain by adding the specified chain
@@ -129,11 +204,55 @@ func (c Chain) Extend(chain Chain) Chain {
 	return newC
 }
 
+// Merge concatenates the given chains, in order, onto c and returns
+// the result as a fresh chain, leaving c and the argument chains
+// untouched.
+//
+//     base := alice.New(m1, m2)
+//     auth := alice.New(authMiddleware)
+//     metrics := alice.New(metricsMiddleware).After(logRequest)
+//     tenant := alice.New(tenantMiddleware)
+//     stdChain := base.Merge(auth, metrics, tenant)
+//     // requests in stdChain go m1 -> m2 -> authMiddleware ->
+//     //   metricsMiddleware -> tenantMiddleware -> handler -> logRequest
+//
+// Merge is equivalent to calling Extend repeatedly, but reads better
+// when composing more than two chains.
+func (c Chain) Merge(chains ...Chain) Chain {
+	merged := c
+	for _, chain := range chains {
+		merged = merged.Extend(chain)
+	}
+	return merged
+}
+
+// Merge concatenates the constructors and endwares of the given
+// chains, in order, and returns the result as a fresh chain.
+//
+//     stdChain := alice.Merge(base, auth, metrics, tenant)
+//
+// is equivalent to:
+//
+//     stdChain := base.Merge(auth, metrics, tenant)
+func Merge(chains ...Chain) Chain {
+	if len(chains) == 0 {
+		return Chain{}
+	}
+	return chains[0].Merge(chains[1:]...)
+}
+
 // Endware is functionality executed after a response
 // is sent to the requester. It is used for any actions the server
 // wishes to take after fulfilling a user's request. Like middleware,
 // it is a func(http.ResponseWriter, *http.Request) so values from
-// the Request or Response can be used.
+// the Request or Response can be used. The ResponseWriter passed in
+// is a *ResponseRecorder, from which the response's status code,
+// size and duration can be read.
+//
+// Endware for a chain all run after every constructor in that chain -
+// including each constructor's own post-processing - has finished, in
+// the order given by the chain's EndwareOrder (EndwareFIFO by default;
+// see WithEndwareOrder).
 //
 // *Note:* This will not let you access values from
 // the Request or the Response that can no longer be used.
@@ -145,7 +264,7 @@ type Endware func(http.ResponseWriter, *http.Request)
 // and the provided endwares. Endwares are executed after both the
 // constructors and the Then() handler are called.
 func (c Chain) After(endwares ...Endware) Chain {
-	return Chain{c.constructors, c.endware}.AppendEndware(endwares...)
+	return Chain{c.constructors, c.endware, c.endwareOrder}.AppendEndware(endwares...)
 }
 
 // AppendEndware extends a chain, adding the specified endware
@@ -163,5 +282,43 @@ func (c Chain) AppendEndware(endwares ...Endware) Chain {
 	newEnds = append(newEnds, c.endware...)
 	newEnds = append(newEnds, endwares...)
 
-	return Chain{c.constructors, newEnds}
+	return Chain{c.constructors, newEnds, c.endwareOrder}
+}
+
+// InsertEndware splices the specified endwares into a chain at pos,
+// shifting the endwares already at pos and beyond to come after them.
+// InsertEndware returns a new chain, leaving the original one untouched
+// and its constructors unchanged.
+//
+// InsertEndware panics if pos is out of range, i.e. pos < 0 or
+// pos > len(c.endware).
+func (c Chain) InsertEndware(pos int, endwares ...Endware) Chain {
+	if pos < 0 || pos > len(c.endware) {
+		panic("alice: Chain.InsertEndware: position out of range")
+	}
+
+	newEnds := make([]Endware, 0, len(c.endware)+len(endwares))
+	newEnds = append(newEnds, c.endware[:pos]...)
+	newEnds = append(newEnds, endwares...)
+	newEnds = append(newEnds, c.endware[pos:]...)
+
+	return Chain{c.constructors, newEnds, c.endwareOrder}
+}
+
+// RemoveEndware returns a new chain with the n endwares starting at
+// pos removed, leaving the original chain and its constructors
+// untouched.
+//
+// RemoveEndware panics if the range is out of bounds, i.e. pos < 0,
+// n < 0, or pos+n > len(c.endware).
+func (c Chain) RemoveEndware(pos, n int) Chain {
+	if pos < 0 || n < 0 || pos+n > len(c.endware) {
+		panic("alice: Chain.RemoveEndware: position out of range")
+	}
+
+	newEnds := make([]Endware, 0, len(c.endware)-n)
+	newEnds = append(newEnds, c.endware[:pos]...)
+	newEnds = append(newEnds, c.endware[pos+n:]...)
+
+	return Chain{c.constructors, newEnds, c.endwareOrder}
 }
@@ -0,0 +1,76 @@
+package alice
+
+import (
+	"net/http"
+)
+
+// If wraps yes so that it is only applied to requests for which pred
+// returns true; requests for which it returns false fall through to
+// next unchanged.
+//
+// The predicate is evaluated at request time, but yes is still only
+// applied to next once, at the point the enclosing chain's Then() is
+// called, so no per-request allocation is introduced.
+//
+//     chain := alice.New(alice.If(isHTML, csrfMiddleware))
+func If(pred func(*http.Request) bool, yes Constructor) Constructor {
+	return func(next http.Handler) http.Handler {
+		yesNext := yes(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if pred(r) {
+				yesNext.ServeHTTP(w, r)
+			} else {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// IfElse wraps yes and no so that yes is applied to requests for
+// which pred returns true and no is applied to the rest.
+//
+// As with If, both yes and no are applied to next only once, at
+// Then() time.
+//
+//     chain := alice.New(alice.IfElse(acceptsGzip, gzipMiddleware, identityMiddleware))
+func IfElse(pred func(*http.Request) bool, yes, no Constructor) Constructor {
+	return func(next http.Handler) http.Handler {
+		yesNext := yes(next)
+		noNext := no(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if pred(r) {
+				yesNext.ServeHTTP(w, r)
+			} else {
+				noNext.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// When returns a new chain that applies sub in its entirety -
+// constructors, endware and all - to requests for which pred returns
+// true, and leaves other requests to c alone.
+//
+// sub is built into a single http.Handler once, at the point the
+// returned chain's Then() is called; pred is then evaluated per
+// request to pick between that handler and the rest of c.
+//
+//     htmlOnly := alice.New(csrfMiddleware).After(logHTMLRequest)
+//     stdChain := alice.New(m1, m2).When(isHTML, htmlOnly)
+//     // requests matching isHTML go m1 -> m2 -> csrfMiddleware -> handler -> logHTMLRequest
+//     // requests not matching go  m1 -> m2 -> handler
+func (c Chain) When(pred func(*http.Request) bool, sub Chain) Chain {
+	return c.Append(func(next http.Handler) http.Handler {
+		subNext := sub.Then(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if pred(r) {
+				subNext.ServeHTTP(w, r)
+			} else {
+				next.ServeHTTP(w, r)
+			}
+		})
+	})
+}
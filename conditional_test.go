@@ -0,0 +1,122 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func taggingConstructor(tag string) Constructor {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Tags", tag)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestIf(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicate bool
+		wantTags  []string
+	}{
+		{"predicate true applies yes", true, []string{"yes"}},
+		{"predicate false falls through", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pred := func(r *http.Request) bool { return tt.predicate }
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			chain := New(If(pred, taggingConstructor("yes")))
+			req := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+			chain.Then(h).ServeHTTP(w, req)
+
+			got := w.Header().Values("X-Tags")
+			if !equalTags(got, tt.wantTags) {
+				t.Fatalf("X-Tags = %v, want %v", got, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestIfElse(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicate bool
+		wantTags  []string
+	}{
+		{"predicate true applies yes", true, []string{"yes"}},
+		{"predicate false applies no", false, []string{"no"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pred := func(r *http.Request) bool { return tt.predicate }
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			chain := New(IfElse(pred, taggingConstructor("yes"), taggingConstructor("no")))
+			req := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+			chain.Then(h).ServeHTTP(w, req)
+
+			got := w.Header().Values("X-Tags")
+			if !equalTags(got, tt.wantTags) {
+				t.Fatalf("X-Tags = %v, want %v", got, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestChainWhen(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicate bool
+		wantTags  []string
+	}{
+		{"predicate true applies sub-chain", true, []string{"base", "sub", "end"}},
+		{"predicate false skips sub-chain", false, []string{"base"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pred := func(r *http.Request) bool { return tt.predicate }
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			sub := New(taggingConstructor("sub")).After(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Tags", "end")
+			})
+			chain := New(taggingConstructor("base")).When(pred, sub)
+
+			req := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+			chain.Then(h).ServeHTTP(w, req)
+
+			got := w.Header().Values("X-Tags")
+			if !equalTags(got, tt.wantTags) {
+				t.Fatalf("X-Tags = %v, want %v", got, tt.wantTags)
+			}
+		})
+	}
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
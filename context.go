@@ -0,0 +1,181 @@
+package alice
+
+import (
+	"context"
+	"net/http"
+)
+
+// A constructor for a piece of context-aware middleware.
+// Unlike Constructor, a ContextConstructor wraps a ContextHandler
+// and can read or derive values from the request's context.Context,
+// such as auth, tracing, or request IDs.
+type ContextConstructor func(ContextHandler) ContextHandler
+
+// ContextHandler is an http.Handler that receives an explicit
+// context.Context and may fail. Returning a non-nil error hands
+// control to the chain's ErrorHandler instead of writing a response
+// directly.
+type ContextHandler func(context.Context, http.ResponseWriter, *http.Request) error
+
+// ErrorHandler turns an error returned by a ContextHandler (or any
+// ContextConstructor in the chain) into a response. The default
+// ErrorHandler used by NewContext responds with a generic 500.
+type ErrorHandler func(http.ResponseWriter, *http.Request, error)
+
+// DefaultErrorHandler is used by NewContext when no ErrorHandler
+// is supplied. It writes a plain 500 response and discards the error.
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// ContextChain acts as a list of ContextConstructors, analogous to
+// Chain but for middleware that is threaded an explicit context.Context.
+// ContextChain is effectively immutable:
+// once created, it will always hold the same set of constructors,
+// base context and error handler.
+type ContextChain struct {
+	ctx          context.Context
+	constructors []ContextConstructor
+	errorHandler ErrorHandler
+}
+
+// NewContext creates a new ContextChain, seeding it with the given
+// base context and memorizing the given list of middleware constructors.
+// NewContext serves no other function, constructors are only called
+// upon a call to Then().
+//
+// If ctx is nil, context.Background() is used. The chain's error
+// handler defaults to DefaultErrorHandler; use WithErrorHandler to
+// override it.
+func NewContext(ctx context.Context, cs ...ContextConstructor) ContextChain {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return ContextChain{
+		ctx:          ctx,
+		constructors: append(([]ContextConstructor)(nil), cs...),
+		errorHandler: DefaultErrorHandler,
+	}
+}
+
+// WithErrorHandler returns a new ContextChain identical to c but
+// using eh to handle errors returned from the chain's handlers.
+func (c ContextChain) WithErrorHandler(eh ErrorHandler) ContextChain {
+	return ContextChain{c.ctx, c.constructors, eh}
+}
+
+// Append extends a ContextChain, adding the specified constructors
+// as the last ones in the request flow.
+//
+// Append returns a new ContextChain, leaving the original one untouched.
+func (c ContextChain) Append(constructors ...ContextConstructor) ContextChain {
+	newCons := make([]ContextConstructor, 0, len(c.constructors)+len(constructors))
+	newCons = append(newCons, c.constructors...)
+	newCons = append(newCons, constructors...)
+
+	return ContextChain{c.ctx, newCons, c.errorHandler}
+}
+
+// wrapErrKey is the context key Wrap uses to thread a per-request
+// error slot through the wrapped plain Constructor, which only knows
+// how to propagate an http.Handler's response, not an error return
+// value.
+type wrapErrKey struct{}
+
+// Wrap adapts a plain Constructor, one built to wrap an http.Handler,
+// for use in a ContextChain. The resulting ContextConstructor passes
+// the context through r.WithContext so it survives the wrapped
+// middleware unchanged.
+//
+// As with every other ContextConstructor, c is invoked exactly once,
+// at Then() time - not per request - so stateful middleware (e.g. one
+// that compiles a regexp or registers a handler on construction)
+// behaves the same as it would in a plain Chain.
+//
+// Caveat: next's returned error is threaded back out of c through a
+// value stashed on the request's context. If c's http.Handler calls
+// the downstream handler with a request carrying a context that isn't
+// derived from the one it was given - e.g. r.WithContext(brandNewCtx)
+// instead of r.WithContext(parentCtx) - that value is no longer
+// reachable and the error is silently dropped instead of reaching the
+// chain's ErrorHandler. Well-behaved middleware always derives from
+// the request it's given, so this only bites constructors that
+// replace the context wholesale.
+//
+//     chain := alice.NewContext(ctx, alice.Wrap(someMiddleware))
+func Wrap(c Constructor) ContextConstructor {
+	return func(next ContextHandler) ContextHandler {
+		inner := c(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := next(r.Context(), w, r)
+			if errSlot, ok := r.Context().Value(wrapErrKey{}).(*error); ok {
+				*errSlot = err
+			}
+		}))
+
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			var err error
+			ctx = context.WithValue(ctx, wrapErrKey{}, &err)
+			inner.ServeHTTP(w, r.WithContext(ctx))
+			return err
+		}
+	}
+}
+
+// Then chains the context-aware middleware and returns the final
+// http.Handler. The chain's base context's values are made visible on
+// the incoming request via r.WithContext before the first constructor
+// runs, so every ContextHandler in the chain can read values placed
+// there. The incoming request's own context - and with it, net/http's
+// own deadline and client-disconnect cancellation - is kept as the
+// parent, so request cancellation keeps working; only Value lookups
+// fall back to the chain's base context.
+// If any constructor or h returns an error, it is passed to the
+// chain's ErrorHandler instead of being silently dropped.
+//
+// Then() treats nil as a handler that does nothing.
+func (c ContextChain) Then(h ContextHandler) http.Handler {
+	if h == nil {
+		h = func(context.Context, http.ResponseWriter, *http.Request) error { return nil }
+	}
+
+	for i := range c.constructors {
+		h = c.constructors[len(c.constructors)-1-i](h)
+	}
+
+	eh := c.errorHandler
+	if eh == nil {
+		eh = DefaultErrorHandler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(seedContext(r.Context(), c.ctx))
+		if err := h(r.Context(), w, r); err != nil {
+			eh(w, r, err)
+		}
+	})
+}
+
+// seedContext returns a context.Context that cancels and expires
+// along with base (the request's own context), but whose Value calls
+// fall back to extra when a key isn't found in base. This lets a
+// ContextChain's base context carry request-independent values (auth
+// config, tracing setup, ...) without discarding the per-request
+// deadline and cancellation that net/http already attached to base.
+func seedContext(base, extra context.Context) context.Context {
+	if extra == nil {
+		return base
+	}
+	return seededContext{Context: base, extra: extra}
+}
+
+type seededContext struct {
+	context.Context
+	extra context.Context
+}
+
+func (c seededContext) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.extra.Value(key)
+}
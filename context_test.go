@@ -0,0 +1,121 @@
+package alice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextChainThenPreservesRequestCancellation(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	ctx, cancel := context.WithCancel(req.Context())
+	req = req.WithContext(ctx)
+	cancel()
+
+	var gotErr error
+	chain := NewContext(context.Background())
+	h := chain.Then(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		gotErr = ctx.Err()
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if !errors.Is(gotErr, context.Canceled) {
+		t.Fatalf("ctx.Err() = %v, want context.Canceled: Then discarded the request's own cancellation", gotErr)
+	}
+}
+
+func TestContextChainThenSeedsBaseContextValues(t *testing.T) {
+	type key struct{}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	var gotValue interface{}
+
+	chain := NewContext(context.WithValue(context.Background(), key{}, "seed"))
+	h := chain.Then(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		gotValue = ctx.Value(key{})
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if gotValue != "seed" {
+		t.Fatalf("ctx.Value(key{}) = %v, want %q", gotValue, "seed")
+	}
+}
+
+func TestWrapSurfacesErrorToErrorHandler(t *testing.T) {
+	wantErr := errors.New("boom")
+	var gotErr error
+
+	passthrough := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	chain := NewContext(context.Background(), Wrap(passthrough)).
+		WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			gotErr = err
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		})
+
+	h := chain.Then(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return wantErr
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if gotErr != wantErr {
+		t.Errorf("ErrorHandler got err %v, want %v", gotErr, wantErr)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestWrapBuildsMiddlewareOnce(t *testing.T) {
+	var constructCount int
+
+	countingConstructor := func(next http.Handler) http.Handler {
+		constructCount++
+		return next
+	}
+
+	chain := NewContext(context.Background(), Wrap(countingConstructor))
+	h := chain.Then(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+	}
+
+	if constructCount != 1 {
+		t.Errorf("constructor ran %d times across 5 requests, want 1", constructCount)
+	}
+}
+
+func TestDefaultErrorHandler(t *testing.T) {
+	chain := NewContext(context.Background())
+	h := chain.Then(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errors.New("boom")
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
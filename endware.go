@@ -0,0 +1,159 @@
+package alice
+
+import (
+	"net/http"
+	"time"
+)
+
+// EndwareOrder controls the order in which a chain's endwares run
+// relative to the order they were added via After/AppendEndware/
+// Extend/Merge.
+type EndwareOrder int
+
+const (
+	// EndwareFIFO runs endwares in the order they were added. This
+	// is the default, and matches how Append/Extend/Merge already
+	// read: the chain grows left to right, and so does its endware.
+	EndwareFIFO EndwareOrder = iota
+
+	// EndwareLIFO runs endwares in the reverse of the order they were
+	// added, mirroring how constructors nest (the last one appended
+	// is the first to run). Useful when an endware added later is
+	// meant to wrap or supersede one added earlier, e.g. a per-route
+	// access logger that should run before a global metrics endware.
+	EndwareLIFO
+)
+
+// WithEndwareOrder returns a new chain identical to c but running its
+// endware in the given order. The default order, used by New and
+// unless WithEndwareOrder is called, is EndwareFIFO.
+func (c Chain) WithEndwareOrder(order EndwareOrder) Chain {
+	return Chain{c.constructors, c.endware, order}
+}
+
+// ResponseInfo is what endware should type-assert w to, rather than
+// asserting the concrete *ResponseRecorder type directly: depending on
+// whether the real underlying http.ResponseWriter implements
+// http.Flusher, Then hands endware one of two different concrete
+// types, so that w only advertises http.Flusher when the real writer
+// actually supports it (a feature-detecting handler, e.g. one
+// upgrading to a streaming response, would otherwise see a false
+// positive followed by a no-op Flush).
+//
+//     chain.After(func(w http.ResponseWriter, r *http.Request) {
+//         rec := w.(alice.ResponseInfo)
+//         log.Printf("%s %d %dB %s", r.URL.Path, rec.Status(), rec.Size(), rec.Duration())
+//     })
+//
+// http.Hijacker, http.Pusher and io.ReaderFrom are deliberately not
+// forwarded at all, for the same reason: call Unwrap and feature-
+// detect the real http.ResponseWriter instead.
+type ResponseInfo interface {
+	Status() int
+	Size() int
+	Duration() time.Duration
+	Unwrap() http.ResponseWriter
+}
+
+// ResponseRecorder wraps an http.ResponseWriter, capturing the status
+// code and number of bytes written to the response body as it's
+// served. It is passed to endware in place of the real
+// http.ResponseWriter so endware can produce access-log style reports
+// (status, size, duration) without every caller re-implementing the
+// capture. Read its captured state through the ResponseInfo interface
+// rather than asserting *ResponseRecorder directly - see ResponseInfo.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	status      int
+	size        int
+	wroteHeader bool
+	start       time.Time
+}
+
+// newResponseRecorder wraps w for a single request, returning a
+// http.Flusher-satisfying variant only when w itself supports
+// http.Flusher, so feature detection on the result reflects what the
+// real http.ResponseWriter can actually do.
+func newResponseRecorder(w http.ResponseWriter) http.ResponseWriter {
+	rec := &ResponseRecorder{ResponseWriter: w, status: http.StatusOK, start: time.Now()}
+
+	if _, ok := w.(http.Flusher); ok {
+		return flushingResponseRecorder{rec}
+	}
+	return rec
+}
+
+// WriteHeader records status before delegating to the underlying
+// ResponseWriter.
+func (rec *ResponseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written before delegating to the
+// underlying ResponseWriter. A handler that never calls WriteHeader
+// explicitly implicitly sends a 200, which Write records as well.
+func (rec *ResponseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.size += n
+	return n, err
+}
+
+// Status returns the response's status code, defaulting to 200 if the
+// handler never called WriteHeader or Write.
+func (rec *ResponseRecorder) Status() int {
+	return rec.status
+}
+
+// Size returns the number of bytes written to the response body.
+func (rec *ResponseRecorder) Size() int {
+	return rec.size
+}
+
+// Duration returns the time elapsed since the recorder was created,
+// i.e. since the chain's constructors and handler began serving the
+// request.
+func (rec *ResponseRecorder) Duration() time.Duration {
+	return time.Since(rec.start)
+}
+
+// Unwrap returns the underlying http.ResponseWriter, so that
+// http.ResponseController (and anything else using errors.As-style
+// unwrapping) can reach it - for example to set read or write
+// deadlines on the real connection, or to feature-detect an interface
+// ResponseRecorder doesn't forward, such as http.Hijacker or
+// http.Pusher.
+func (rec *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// flushingResponseRecorder adds a real Flush to a *ResponseRecorder.
+// It only ever wraps a ResponseRecorder whose underlying
+// http.ResponseWriter has already been confirmed to implement
+// http.Flusher, so the type assertion in Flush cannot fail.
+type flushingResponseRecorder struct {
+	*ResponseRecorder
+}
+
+// Flush implements http.Flusher by delegating to the underlying
+// ResponseWriter.
+func (rec flushingResponseRecorder) Flush() {
+	rec.ResponseWriter.(http.Flusher).Flush()
+}
+
+func runEndware(endware []Endware, order EndwareOrder, w http.ResponseWriter, r *http.Request) {
+	if order == EndwareLIFO {
+		for i := len(endware) - 1; i >= 0; i-- {
+			endware[i](w, r)
+		}
+		return
+	}
+
+	for _, endwareFn := range endware {
+		endwareFn(w, r)
+	}
+}
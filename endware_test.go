@@ -0,0 +1,240 @@
+package alice
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// nonFlushingWriter is an http.ResponseWriter that implements none of
+// http.Flusher, http.Hijacker or http.Pusher, unlike
+// httptest.ResponseRecorder (which implements Flusher).
+type nonFlushingWriter struct {
+	header http.Header
+	status int
+	body   []byte
+}
+
+func newNonFlushingWriter() *nonFlushingWriter {
+	return &nonFlushingWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+func (w *nonFlushingWriter) Header() http.Header { return w.header }
+
+func (w *nonFlushingWriter) Write(b []byte) (int, error) {
+	w.body = append(w.body, b...)
+	return len(b), nil
+}
+
+func (w *nonFlushingWriter) WriteHeader(status int) { w.status = status }
+
+// hijackableFlushingWriter implements http.Flusher, http.Hijacker and
+// http.Pusher, so the test can assert that only Flusher is advertised
+// by the recorder.
+type hijackableFlushingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w hijackableFlushingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+func (w hijackableFlushingWriter) Push(target string, opts *http.PushOptions) error {
+	return nil
+}
+
+func TestResponseRecorderFeatureDetection(t *testing.T) {
+	t.Run("underlying supports Flusher", func(t *testing.T) {
+		var sawFlusher, sawHijacker, sawPusher bool
+
+		chain := New().After(func(w http.ResponseWriter, r *http.Request) {
+			_, sawFlusher = w.(http.Flusher)
+			_, sawHijacker = w.(http.Hijacker)
+			_, sawPusher = w.(http.Pusher)
+		})
+
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		chain.Then(h).ServeHTTP(hijackableFlushingWriter{httptest.NewRecorder()}, req)
+
+		if !sawFlusher {
+			t.Error("w.(http.Flusher) = false, want true: underlying writer supports Flush")
+		}
+		if sawHijacker {
+			t.Error("w.(http.Hijacker) = true, want false: ResponseRecorder does not forward Hijacker")
+		}
+		if sawPusher {
+			t.Error("w.(http.Pusher) = true, want false: ResponseRecorder does not forward Pusher")
+		}
+	})
+
+	t.Run("underlying does not support Flusher", func(t *testing.T) {
+		var sawFlusher bool
+
+		chain := New().After(func(w http.ResponseWriter, r *http.Request) {
+			_, sawFlusher = w.(http.Flusher)
+		})
+
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		chain.Then(h).ServeHTTP(newNonFlushingWriter(), req)
+
+		if sawFlusher {
+			t.Error("w.(http.Flusher) = true, want false: underlying writer does not support Flush")
+		}
+	})
+
+	t.Run("Unwrap reaches the real writer", func(t *testing.T) {
+		underlying := newNonFlushingWriter()
+
+		chain := New().After(func(w http.ResponseWriter, r *http.Request) {
+			rec := w.(ResponseInfo)
+			if rec.Unwrap() != http.ResponseWriter(underlying) {
+				t.Error("Unwrap() did not return the original ResponseWriter")
+			}
+		})
+
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		chain.Then(h).ServeHTTP(underlying, req)
+	})
+}
+
+func TestEndwareOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		order EndwareOrder
+		want  []string
+	}{
+		{"default is FIFO", EndwareFIFO, []string{"e1", "e2", "e3"}},
+		{"LIFO reverses", EndwareLIFO, []string{"e3", "e2", "e1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			record := func(name string) Endware {
+				return func(w http.ResponseWriter, r *http.Request) {
+					got = append(got, name)
+				}
+			}
+
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			chain := New().
+				After(record("e1"), record("e2"), record("e3")).
+				WithEndwareOrder(tt.order)
+
+			req := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+			chain.Then(h).ServeHTTP(w, req)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestThenNoEndwareDoesNotWrap(t *testing.T) {
+	var sawRecorder bool
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(*ResponseRecorder); ok {
+			sawRecorder = true
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	New().Then(h).ServeHTTP(w, req)
+
+	if sawRecorder {
+		t.Fatal("Then wrapped the ResponseWriter in a ResponseRecorder despite the chain having no endware")
+	}
+}
+
+func TestResponseRecorder(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantSize   int
+	}{
+		{
+			name: "explicit status and body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte("hello"))
+			},
+			wantStatus: http.StatusCreated,
+			wantSize:   5,
+		},
+		{
+			name: "implicit 200 on first write",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("hi"))
+			},
+			wantStatus: http.StatusOK,
+			wantSize:   2,
+		},
+		{
+			name: "header only, no body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			wantStatus: http.StatusNoContent,
+			wantSize:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotStatus, gotSize int
+			var gotDuration time.Duration
+
+			chain := New().After(func(w http.ResponseWriter, r *http.Request) {
+				rec, ok := w.(ResponseInfo)
+				if !ok {
+					t.Fatal("endware did not receive a ResponseInfo")
+				}
+				gotStatus = rec.Status()
+				gotSize = rec.Size()
+				gotDuration = rec.Duration()
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+			chain.Then(tt.handler).ServeHTTP(w, req)
+
+			if gotStatus != tt.wantStatus {
+				t.Errorf("Status() = %d, want %d", gotStatus, tt.wantStatus)
+			}
+			if gotSize != tt.wantSize {
+				t.Errorf("Size() = %d, want %d", gotSize, tt.wantSize)
+			}
+			if gotDuration < 0 {
+				t.Errorf("Duration() = %v, want non-negative", gotDuration)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package alice
+
+import (
+	"net/http"
+)
+
+// ErrHandler is like http.Handler's ServeHTTP, but may return an
+// error instead of writing a failure response itself.
+type ErrHandler func(http.ResponseWriter, *http.Request) error
+
+// ErrConstructor is a constructor for a piece of error-returning
+// middleware, analogous to Constructor but for an ErrChain.
+type ErrConstructor func(ErrHandler) ErrHandler
+
+// ErrEndware is like Endware, but may return an error. Like Endware,
+// it runs after the response has been sent, so returned errors are
+// only useful for logging/reporting; the response itself cannot be
+// altered at that point. Errors returned from ErrEndware are reported
+// to the chain's ErrLogHandler, not its ErrHandlerFunc - see
+// WithEndwareErrorHandler.
+type ErrEndware func(http.ResponseWriter, *http.Request) error
+
+// ErrHandlerFunc turns an error returned by any ErrConstructor or the
+// chain's ErrHandler into a response, a log line, or both. It is the
+// single place an application needs to format or record failures,
+// instead of every middleware doing it independently. Because it
+// receives the http.ResponseWriter, this runs before the response has
+// been written - ErrHandlerFunc is never called for an error returned
+// by ErrEndware; see ErrLogHandler for that.
+type ErrHandlerFunc func(http.ResponseWriter, *http.Request, error)
+
+// ErrLogHandler reports an error returned by an ErrEndware. Unlike
+// ErrHandlerFunc, it has no access to the http.ResponseWriter: by the
+// time endware runs, the response has already been sent, so there is
+// nothing safe left to write. It is meant purely for logging/
+// reporting a failure that happened after the fact.
+type ErrLogHandler func(*http.Request, error)
+
+// ErrChain acts as a list of ErrConstructors and ErrEndwares, funneling
+// any error they return through a single ErrHandlerFunc or
+// ErrLogHandler. ErrChain is effectively immutable: once created, it
+// will always hold the same set of constructors, endware and error
+// handlers.
+type ErrChain struct {
+	constructors        []ErrConstructor
+	endware             []ErrEndware
+	errorHandler        ErrHandlerFunc
+	endwareErrorHandler ErrLogHandler
+}
+
+// NewErrChain creates a new ErrChain, memorizing the given list of
+// error-returning middleware constructors. Errors are funneled to eh;
+// if eh is nil, errors are silently discarded, matching net/http's
+// own "write nothing, the client sees a broken response" behavior
+// for a panic-free default.
+func NewErrChain(eh ErrHandlerFunc, cs ...ErrConstructor) ErrChain {
+	return ErrChain{append(([]ErrConstructor)(nil), cs...), nil, eh, nil}
+}
+
+// WithEndwareErrorHandler returns a new ErrChain identical to c but
+// reporting errors returned by ErrEndware to leh instead of
+// discarding them. leh cannot write to the response, since endware
+// only runs once the response is already complete.
+func (c ErrChain) WithEndwareErrorHandler(leh ErrLogHandler) ErrChain {
+	return ErrChain{c.constructors, c.endware, c.errorHandler, leh}
+}
+
+// Then chains the error-returning middleware and endware and returns
+// the final http.Handler. Any error returned by h or a constructor is
+// passed to the chain's ErrHandlerFunc. Endware still runs even if h
+// returned an error; any error an ErrEndware returns is passed to the
+// chain's ErrLogHandler instead, since the response is already
+// complete by then.
+//
+// Then() treats nil as a handler that does nothing.
+func (c ErrChain) Then(h ErrHandler) http.Handler {
+	if h == nil {
+		h = func(http.ResponseWriter, *http.Request) error { return nil }
+	}
+
+	for i := range c.constructors {
+		h = c.constructors[len(c.constructors)-1-i](h)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := h(w, r)
+		if err != nil && c.errorHandler != nil {
+			c.errorHandler(w, r, err)
+		}
+
+		for _, endwareFn := range c.endware {
+			if endErr := endwareFn(w, r); endErr != nil && c.endwareErrorHandler != nil {
+				c.endwareErrorHandler(r, endErr)
+			}
+		}
+	})
+}
+
+// Append extends an ErrChain, adding the specified constructors as
+// the last ones in the request flow.
+//
+// Append returns a new ErrChain, leaving the original one untouched.
+func (c ErrChain) Append(constructors ...ErrConstructor) ErrChain {
+	newCons := make([]ErrConstructor, 0, len(c.constructors)+len(constructors))
+	newCons = append(newCons, c.constructors...)
+	newCons = append(newCons, constructors...)
+
+	return ErrChain{newCons, c.endware, c.errorHandler, c.endwareErrorHandler}
+}
+
+// After creates a new ErrChain with the current chain's constructors
+// and the provided endwares appended. Endwares are executed after
+// both the constructors and the Then() handler are called.
+func (c ErrChain) After(endwares ...ErrEndware) ErrChain {
+	newEnds := make([]ErrEndware, 0, len(c.endware)+len(endwares))
+	newEnds = append(newEnds, c.endware...)
+	newEnds = append(newEnds, endwares...)
+
+	return ErrChain{c.constructors, newEnds, c.errorHandler, c.endwareErrorHandler}
+}
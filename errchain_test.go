@@ -0,0 +1,148 @@
+package alice
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrChainHandlerError(t *testing.T) {
+	wantErr := errors.New("boom")
+	var gotErr error
+
+	chain := NewErrChain(func(w http.ResponseWriter, r *http.Request, err error) {
+		gotErr = err
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	})
+
+	h := func(w http.ResponseWriter, r *http.Request) error {
+		return wantErr
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	chain.Then(h).ServeHTTP(w, req)
+
+	if gotErr != wantErr {
+		t.Errorf("ErrHandlerFunc got err %v, want %v", gotErr, wantErr)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestErrChainConstructorError(t *testing.T) {
+	wantErr := errors.New("denied")
+	var gotErr error
+
+	failingConstructor := func(next ErrHandler) ErrHandler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			return wantErr
+		}
+	}
+
+	chain := NewErrChain(func(w http.ResponseWriter, r *http.Request, err error) {
+		gotErr = err
+	}, failingConstructor)
+
+	h := func(w http.ResponseWriter, r *http.Request) error {
+		t.Fatal("handler should not run when a constructor already failed")
+		return nil
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	chain.Then(h).ServeHTTP(w, req)
+
+	if gotErr != wantErr {
+		t.Errorf("ErrHandlerFunc got err %v, want %v", gotErr, wantErr)
+	}
+}
+
+func TestErrChainNoErrHandlerDoesNotPanic(t *testing.T) {
+	chain := NewErrChain(nil)
+	h := func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("boom")
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	chain.Then(h).ServeHTTP(w, req)
+}
+
+func TestErrChainEndwareRunsAfterHandlerError(t *testing.T) {
+	var endwareRan bool
+
+	chain := NewErrChain(func(w http.ResponseWriter, r *http.Request, err error) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}).After(func(w http.ResponseWriter, r *http.Request) error {
+		endwareRan = true
+		return nil
+	})
+
+	h := func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("boom")
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	chain.Then(h).ServeHTTP(w, req)
+
+	if !endwareRan {
+		t.Error("endware did not run after the handler returned an error")
+	}
+}
+
+func TestErrChainEndwareErrorGoesToLogHandlerNotErrHandler(t *testing.T) {
+	var handlerCalls int
+	var loggedErr error
+	var loggedReq *http.Request
+	wantErr := errors.New("endware failed")
+
+	chain := NewErrChain(func(w http.ResponseWriter, r *http.Request, err error) {
+		handlerCalls++
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}).After(func(w http.ResponseWriter, r *http.Request) error {
+		return wantErr
+	}).WithEndwareErrorHandler(func(r *http.Request, err error) {
+		loggedErr = err
+		loggedReq = r
+	})
+
+	h := func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	chain.Then(h).ServeHTTP(w, req)
+
+	if handlerCalls != 0 {
+		t.Errorf("ErrHandlerFunc was called %d times for an endware error, want 0", handlerCalls)
+	}
+	if loggedErr != wantErr {
+		t.Errorf("ErrLogHandler got err %v, want %v", loggedErr, wantErr)
+	}
+	if loggedReq != req {
+		t.Error("ErrLogHandler did not receive the request")
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d (the handler's own response, untouched by the later endware error)", w.Code, http.StatusOK)
+	}
+}
+
+func TestErrChainEndwareErrorDiscardedWithoutLogHandler(t *testing.T) {
+	chain := NewErrChain(nil).After(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("boom")
+	})
+
+	h := func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	chain.Then(h).ServeHTTP(w, req)
+}
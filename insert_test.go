@@ -0,0 +1,265 @@
+package alice
+
+import "testing"
+
+func TestChainInsert(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  int
+		want []string
+	}{
+		{"insert at start", 0, []string{"new", "a", "b", "c"}},
+		{"insert in middle", 1, []string{"a", "new", "b", "c"}},
+		{"insert at end", 3, []string{"a", "b", "c", "new"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := New(taggingConstructor("a"), taggingConstructor("b"), taggingConstructor("c"))
+			inserted := base.Insert(tt.pos, taggingConstructor("new"))
+
+			got := runChain(inserted)
+			if !equalTags(got, tt.want) {
+				t.Fatalf("X-Tags = %v, want %v", got, tt.want)
+			}
+
+			// the original chain must be untouched
+			if got := runChain(base); !equalTags(got, []string{"a", "b", "c"}) {
+				t.Fatalf("Insert mutated the original chain: got tags %v", got)
+			}
+		})
+	}
+}
+
+func TestChainInsertOutOfRangePanics(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  int
+	}{
+		{"negative position", -1},
+		{"position past the end", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("Insert(%d, ...) did not panic", tt.pos)
+				}
+			}()
+
+			base := New(taggingConstructor("a"), taggingConstructor("b"), taggingConstructor("c"))
+			base.Insert(tt.pos, taggingConstructor("new"))
+		})
+	}
+}
+
+func TestChainInsertLeavesEndwareUntouched(t *testing.T) {
+	var endwareTags []string
+
+	base := New(taggingConstructor("a")).After(taggingEndware(&endwareTags, "e1"))
+	inserted := base.Insert(0, taggingConstructor("new"))
+
+	runChain(inserted)
+	if !equalTags(endwareTags, []string{"e1"}) {
+		t.Fatalf("endware tags = %v, want %v", endwareTags, []string{"e1"})
+	}
+}
+
+func TestChainRemove(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  int
+		n    int
+		want []string
+	}{
+		{"remove from start", 0, 1, []string{"b", "c"}},
+		{"remove from middle", 1, 1, []string{"a", "c"}},
+		{"remove from end", 2, 1, []string{"a", "b"}},
+		{"remove a run", 0, 2, []string{"c"}},
+		{"n == 0 is a no-op", 1, 0, []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := New(taggingConstructor("a"), taggingConstructor("b"), taggingConstructor("c"))
+			removed := base.Remove(tt.pos, tt.n)
+
+			got := runChain(removed)
+			if !equalTags(got, tt.want) {
+				t.Fatalf("X-Tags = %v, want %v", got, tt.want)
+			}
+
+			// the original chain must be untouched
+			if got := runChain(base); !equalTags(got, []string{"a", "b", "c"}) {
+				t.Fatalf("Remove mutated the original chain: got tags %v", got)
+			}
+		})
+	}
+}
+
+func TestChainRemoveOutOfRangePanics(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  int
+		n    int
+	}{
+		{"negative position", -1, 1},
+		{"negative n", 0, -1},
+		{"range past the end", 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("Remove(%d, %d) did not panic", tt.pos, tt.n)
+				}
+			}()
+
+			base := New(taggingConstructor("a"), taggingConstructor("b"), taggingConstructor("c"))
+			base.Remove(tt.pos, tt.n)
+		})
+	}
+}
+
+func TestChainRemoveLeavesEndwareUntouched(t *testing.T) {
+	var endwareTags []string
+
+	base := New(taggingConstructor("a"), taggingConstructor("b")).After(taggingEndware(&endwareTags, "e1"))
+	removed := base.Remove(0, 1)
+
+	runChain(removed)
+	if !equalTags(endwareTags, []string{"e1"}) {
+		t.Fatalf("endware tags = %v, want %v", endwareTags, []string{"e1"})
+	}
+}
+
+func TestChainInsertEndware(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  int
+		want []string
+	}{
+		{"insert at start", 0, []string{"new", "e1", "e2"}},
+		{"insert in middle", 1, []string{"e1", "new", "e2"}},
+		{"insert at end", 2, []string{"e1", "e2", "new"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var order []string
+
+			base := New().After(taggingEndware(&order, "e1"), taggingEndware(&order, "e2"))
+			inserted := base.InsertEndware(tt.pos, taggingEndware(&order, "new"))
+
+			runChain(inserted)
+			if !equalTags(order, tt.want) {
+				t.Fatalf("endware order = %v, want %v", order, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainInsertEndwareOutOfRangePanics(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  int
+	}{
+		{"negative position", -1},
+		{"position past the end", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("InsertEndware(%d, ...) did not panic", tt.pos)
+				}
+			}()
+
+			var order []string
+			base := New().After(taggingEndware(&order, "e1"), taggingEndware(&order, "e2"))
+			base.InsertEndware(tt.pos, taggingEndware(&order, "new"))
+		})
+	}
+}
+
+func TestChainInsertEndwareLeavesConstructorsUntouched(t *testing.T) {
+	var order []string
+
+	base := New(taggingConstructor("a")).After(taggingEndware(&order, "e1"))
+	inserted := base.InsertEndware(0, taggingEndware(&order, "new"))
+
+	got := runChain(inserted)
+	if !equalTags(got, []string{"a"}) {
+		t.Fatalf("X-Tags = %v, want %v", got, []string{"a"})
+	}
+}
+
+func TestChainRemoveEndware(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  int
+		n    int
+		want []string
+	}{
+		{"remove from start", 0, 1, []string{"e2", "e3"}},
+		{"remove from middle", 1, 1, []string{"e1", "e3"}},
+		{"remove from end", 2, 1, []string{"e1", "e2"}},
+		{"remove a run", 0, 2, []string{"e3"}},
+		{"n == 0 is a no-op", 1, 0, []string{"e1", "e2", "e3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var order []string
+
+			base := New().After(taggingEndware(&order, "e1"), taggingEndware(&order, "e2"), taggingEndware(&order, "e3"))
+			removed := base.RemoveEndware(tt.pos, tt.n)
+
+			runChain(removed)
+			if !equalTags(order, tt.want) {
+				t.Fatalf("endware order = %v, want %v", order, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainRemoveEndwareOutOfRangePanics(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  int
+		n    int
+	}{
+		{"negative position", -1, 1},
+		{"negative n", 0, -1},
+		{"range past the end", 1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("RemoveEndware(%d, %d) did not panic", tt.pos, tt.n)
+				}
+			}()
+
+			var order []string
+			base := New().After(taggingEndware(&order, "e1"), taggingEndware(&order, "e2"))
+			base.RemoveEndware(tt.pos, tt.n)
+		})
+	}
+}
+
+func TestChainRemoveEndwareLeavesConstructorsUntouched(t *testing.T) {
+	var order []string
+
+	base := New(taggingConstructor("a")).After(taggingEndware(&order, "e1"), taggingEndware(&order, "e2"))
+	removed := base.RemoveEndware(0, 1)
+
+	got := runChain(removed)
+	if !equalTags(got, []string{"a"}) {
+		t.Fatalf("X-Tags = %v, want %v", got, []string{"a"})
+	}
+}
@@ -0,0 +1,99 @@
+package alice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func taggingEndware(tags *[]string, tag string) Endware {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*tags = append(*tags, tag)
+	}
+}
+
+// runChain serves one request through chain and returns the
+// constructor tags added to the X-Tags response header, in the order
+// they ran.
+func runChain(chain Chain) []string {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	chain.Then(h).ServeHTTP(w, req)
+
+	return w.Header().Values("X-Tags")
+}
+
+func TestChainMergeMethod(t *testing.T) {
+	var endwareTags []string
+
+	base := New(taggingConstructor("base"))
+	auth := New(taggingConstructor("auth"))
+	metrics := New(taggingConstructor("metrics")).After(taggingEndware(&endwareTags, "metrics-log"))
+	tenant := New(taggingConstructor("tenant"))
+
+	merged := base.Merge(auth, metrics, tenant)
+
+	constructorTags := runChain(merged)
+	wantConstructors := []string{"base", "auth", "metrics", "tenant"}
+	if !equalTags(constructorTags, wantConstructors) {
+		t.Fatalf("X-Tags = %v, want %v", constructorTags, wantConstructors)
+	}
+	if !equalTags(endwareTags, []string{"metrics-log"}) {
+		t.Fatalf("endware tags = %v, want %v", endwareTags, []string{"metrics-log"})
+	}
+
+	// the originals must be untouched
+	baseTags := runChain(base)
+	if !equalTags(baseTags, []string{"base"}) {
+		t.Fatalf("base chain was mutated by Merge: got tags %v", baseTags)
+	}
+}
+
+func TestMergeFunction(t *testing.T) {
+	base := New(taggingConstructor("base"))
+	auth := New(taggingConstructor("auth"))
+	tenant := New(taggingConstructor("tenant"))
+
+	got := runChain(Merge(base, auth, tenant))
+	want := []string{"base", "auth", "tenant"}
+	if !equalTags(got, want) {
+		t.Fatalf("X-Tags = %v, want %v", got, want)
+	}
+}
+
+func TestMergeFunctionEmpty(t *testing.T) {
+	got := runChain(Merge())
+	if len(got) != 0 {
+		t.Fatalf("X-Tags = %v, want empty", got)
+	}
+}
+
+func TestChainMergeNoArgsReturnsEquivalentChain(t *testing.T) {
+	base := New(taggingConstructor("base"))
+	got := runChain(base.Merge())
+	if !equalTags(got, []string{"base"}) {
+		t.Fatalf("X-Tags = %v, want %v", got, []string{"base"})
+	}
+}
+
+func TestChainMergePreservesEndwareOrder(t *testing.T) {
+	var order []string
+
+	a := New().After(taggingEndware(&order, "a1"), taggingEndware(&order, "a2")).
+		WithEndwareOrder(EndwareLIFO)
+	b := New().After(taggingEndware(&order, "b1"))
+
+	merged := a.Merge(b)
+	runChain(merged)
+
+	// EndwareLIFO is inherited from the receiver (a) and applies to
+	// the whole concatenated endware list [a1, a2, b1] in reverse.
+	want := []string{"b1", "a2", "a1"}
+	if !equalTags(order, want) {
+		t.Fatalf("endware order = %v, want %v", order, want)
+	}
+}